@@ -0,0 +1,100 @@
+package helm_client
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ociRegistryEntry records an OCI registry that registryAdd logged in to,
+// so ResolveDependencies can later look up which registry a file://-free,
+// oci://-prefixed dependency repository refers to. repositories.yaml has
+// no concept of OCI entries (there's no index.yaml to track), so this is
+// a small parallel index rather than reusing repo.File.
+type ociRegistryEntry struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// ociRegistryIndexPath returns the path of the OCI registry index, kept
+// alongside Helm's registry config file.
+func ociRegistryIndexPath(cli *baseClient) string {
+	return filepath.Join(filepath.Dir(cli.settings.RegistryConfig), "oci-repositories.yaml")
+}
+
+// registerOCIRepo records that name refers to the OCI registry at url, so
+// that a later ResolveDependencies call can find it again. Callers must
+// already hold the repo lock for cli.settings.RegistryConfig, since this
+// file is written alongside it.
+func (cli *baseClient) registerOCIRepo(name, url string) error {
+	path := ociRegistryIndexPath(cli)
+
+	entries, err := readOCIRegistryIndex(path)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i, e := range entries {
+		if e.Name == name {
+			entries[i].URL = url
+			found = true
+			break
+		}
+	}
+	if !found {
+		entries = append(entries, ociRegistryEntry{Name: name, URL: url})
+	}
+
+	b, err := yaml.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal OCI registry index")
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// ociRepoNameForURL looks up the registered name for an oci:// repository
+// URL, mirroring repoNameForURL's lookup for HTTP repos.
+func (cli *baseClient) ociRepoNameForURL(url string) (string, error) {
+	// lock registry config, shared with registryAdd via repositoryLock
+	unlock, err := acquireRepoLock(cli.settings.RegistryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
+
+	entries, err := readOCIRegistryIndex(ociRegistryIndexPath(cli))
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		if e.URL == url {
+			return e.Name, nil
+		}
+	}
+	return "", errors.Errorf("no OCI registry registered for url %q, run repo add first", url)
+}
+
+func readOCIRegistryIndex(path string) ([]ociRegistryEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []ociRegistryEntry
+	if err := yaml.Unmarshal(b, &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to parse OCI registry index")
+	}
+	return entries, nil
+}
@@ -0,0 +1,70 @@
+package helm_client
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// stdinMu serializes reads from the process-wide os.Stdin when
+// resolvePassword falls back to it. cli.passwordReader is usually set to
+// something request-scoped by the caller; os.Stdin is the one reader every
+// baseClient in the process shares, so without this lock two concurrent
+// repoAdd(PasswordFromStdin: true) calls would race on the same underlying
+// stream and could each read a mangled half of the other's password.
+var stdinMu sync.Mutex
+
+// resolvePassword fills in o.Password from cli's configured password reader
+// (cli.passwordReader, defaulting to os.Stdin) when the caller asked for a
+// username but didn't supply a password inline. This lets callers pipe
+// credentials in from CI jobs or prompt operators interactively instead of
+// baking secrets into request bodies, matching Helm CLI's
+// `repo add --password-stdin`.
+//
+// Note this default only really makes sense for a short-lived CLI
+// invocation. In a long-running server process, os.Stdin is rarely
+// connected to anything meaningful: depending on how the process was
+// started, this will either block the request goroutine forever waiting
+// for bytes that never arrive, or return EOF immediately and resolve to an
+// empty password. Server-style callers should set cli.passwordReader to a
+// request-scoped reader (e.g. the body of the incoming request) instead of
+// relying on the stdin fallback.
+func (cli *baseClient) resolvePassword(o *RepoAddOptions) error {
+	if !o.PasswordFromStdin || o.Username == "" || o.Password != "" {
+		return nil
+	}
+
+	reader := cli.passwordReader
+	if reader == nil {
+		stdinMu.Lock()
+		defer stdinMu.Unlock()
+		reader = os.Stdin
+	}
+
+	if f, ok := reader.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		b, err := term.ReadPassword(int(f.Fd()))
+		if err != nil {
+			return errors.Wrap(err, "failed to read password")
+		}
+		o.Password = string(b)
+		return nil
+	}
+
+	password, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to read password")
+	}
+	o.Password = trimNewline(password)
+	return nil
+}
+
+func trimNewline(s string) string {
+	for len(s) > 0 && (s[len(s)-1] == '\n' || s[len(s)-1] == '\r') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package helm_client
+
+import (
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// isOCIRepo reports whether url points at an OCI registry rather than a
+// classic HTTP index.yaml chart repository.
+func isOCIRepo(url string) bool {
+	return strings.HasPrefix(url, "oci://")
+}
+
+// registryAdd logs in to the OCI registry described by o and persists the
+// credentials in Helm's registry config file (cli.settings.RegistryConfig),
+// mirroring what repoAdd does for repositories.yaml with HTTP repos. Unlike
+// HTTP repos, OCI registries have no index to download and no entry is
+// written to repositories.yaml.
+//
+// This package has no install/upgrade/search surface of its own today — it
+// only manages repo/registry connections and dependency resolution — so
+// there is nothing here for oci://... references to be resolved against
+// besides ResolveDependencies (resolve_dependencies.go), which is the only
+// consumer of a registered OCI registry. Wiring oci:// support into chart
+// install/upgrade/search is out of scope until this package grows those
+// flows.
+func (cli *baseClient) registryAdd(o *RepoAddOptions) error {
+	host := strings.TrimPrefix(o.URL, "oci://")
+
+	// registryAdd writes cli.settings.RegistryConfig, so it shares the same
+	// lock subsystem repoAdd/repoUpdate/repoRemove use for repositories.yaml.
+	unlock, err := acquireRepoLock(cli.settings.RegistryConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
+
+	regClient, err := registry.NewClient(
+		registry.ClientOptWriter(log.Writer()),
+		registry.ClientOptCredentialsFile(cli.settings.RegistryConfig),
+		registry.ClientOptEnableCache(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create registry client")
+	}
+	cli.registryClient = regClient
+
+	if err := cli.registerOCIRepo(o.Name, o.URL); err != nil {
+		return err
+	}
+
+	// A bare registry with no credentials is valid (e.g. a public ghcr.io
+	// chart repo); there's nothing further to log in with.
+	if o.Username == "" {
+		return nil
+	}
+
+	loginOpts := []registry.LoginOption{
+		registry.LoginOptBasicAuth(o.Username, o.Password),
+		registry.LoginOptInsecure(o.InsecureSkipTLSverify),
+	}
+	if o.CertFile != "" && o.KeyFile != "" {
+		loginOpts = append(loginOpts, registry.LoginOptTLSClientConfig(o.CertFile, o.KeyFile, o.CaFile))
+	}
+
+	if err := cli.registryClient.Login(host, loginOpts...); err != nil {
+		return errors.Wrapf(err, "failed to log in to registry %q", host)
+	}
+	return nil
+}
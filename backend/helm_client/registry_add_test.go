@@ -0,0 +1,29 @@
+package helm_client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestRegistryAddAllowsAnonymousRegistry(t *testing.T) {
+	dir := t.TempDir()
+	settings := cli.New()
+	settings.RegistryConfig = filepath.Join(dir, "registry.json")
+
+	c := &baseClient{settings: settings}
+	o := &RepoAddOptions{Name: "public", URL: "oci://ghcr.io/example/charts"}
+
+	if err := c.registryAdd(o); err != nil {
+		t.Fatalf("expected adding an anonymous OCI registry to succeed, got: %v", err)
+	}
+
+	name, err := c.ociRepoNameForURL(o.URL)
+	if err != nil {
+		t.Fatalf("expected registry to be recorded for later dependency resolution: %v", err)
+	}
+	if name != o.Name {
+		t.Fatalf("got registered name %q, want %q", name, o.Name)
+	}
+}
@@ -1,14 +1,11 @@
 package helm_client
 
 import (
-	"context"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
-	"github.com/gofrs/flock"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/getter"
@@ -29,9 +26,21 @@ type RepoAddOptions struct {
 
 	NoRepoExsitsError bool // When set to true, no error will be returned when the same repo exists.
 	UpdateWhenExsits  bool // --force-update
+
+	AllowDeprecatedRepos bool // When set to true, deprecated repo URLs are rewritten to their replacement instead of rejected.
+
+	PasswordFromStdin bool // --password-stdin
 }
 
 func (cli *baseClient) repoAdd(o *RepoAddOptions) error {
+	if err := cli.resolvePassword(o); err != nil {
+		return err
+	}
+
+	if isOCIRepo(o.URL) {
+		return cli.registryAdd(o)
+	}
+
 	repoFile := cli.settings.RepositoryConfig
 	repoCache := cli.settings.RepositoryCache
 
@@ -41,28 +50,16 @@ func (cli *baseClient) repoAdd(o *RepoAddOptions) error {
 		return err
 	}
 
-	// lock repo file
-	repoFileExt := filepath.Ext(repoFile)
-	var lockPath string
-	if len(repoFileExt) > 0 && len(repoFileExt) < len(repoFile) {
-		lockPath = strings.TrimSuffix(repoFile, repoFileExt) + ".lock"
-	} else {
-		lockPath = repoFile + ".lock"
-	}
-	fileLock := flock.New(lockPath)
-	lockCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
-	if err == nil && locked {
-		defer func() {
-			if err := fileLock.Unlock(); err != nil {
-				log.Printf("Failed to unlock file: %v", err)
-			}
-		}()
-	}
+	// lock repo file, shared with repoUpdate/repoRemove via repositoryLock
+	unlock, err := acquireRepoLock(repoFile)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
 
 	// read repo file
 	b, err := os.ReadFile(repoFile)
@@ -75,9 +72,14 @@ func (cli *baseClient) repoAdd(o *RepoAddOptions) error {
 		return err
 	}
 
+	url, err := checkDeprecatedRepo(o.URL, o.AllowDeprecatedRepos)
+	if err != nil {
+		return err
+	}
+
 	c := repo.Entry{
 		Name:                  o.Name,
-		URL:                   o.URL,
+		URL:                   url,
 		Username:              o.Username,
 		Password:              o.Password,
 		PassCredentialsAll:    o.PassCredentialsAll,
@@ -121,7 +123,7 @@ func (cli *baseClient) repoAdd(o *RepoAddOptions) error {
 		r.CachePath = repoCache
 	}
 	if _, err := r.DownloadIndexFile(); err != nil {
-		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", o.URL)
+		return errors.Wrapf(err, "looks like %q is not a valid chart repository or cannot be reached", url)
 	}
 
 	f.Update(&c)
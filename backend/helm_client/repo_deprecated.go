@@ -0,0 +1,32 @@
+package helm_client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// deprecatedRepos maps substrings of retired Helm chart repository URLs to
+// the replacement URL that should be used instead. New deprecations can be
+// added here as chart repositories are retired.
+var deprecatedRepos = map[string]string{
+	"kubernetes-charts.storage.googleapis.com":           "https://charts.helm.sh/stable",
+	"kubernetes-charts-incubator.storage.googleapis.com": "https://charts.helm.sh/incubator",
+}
+
+// checkDeprecatedRepo looks for a known-deprecated chart repository URL. If
+// found and allowDeprecated is false, it returns a descriptive error
+// recommending the replacement URL. If allowDeprecated is true, it instead
+// returns the replacement URL so the caller can rewrite the entry and
+// proceed.
+func checkDeprecatedRepo(url string, allowDeprecated bool) (string, error) {
+	for deprecated, replacement := range deprecatedRepos {
+		if strings.Contains(url, deprecated) {
+			if !allowDeprecated {
+				return "", errors.Errorf("repo %q is deprecated and will not receive updates, please use %q instead; set AllowDeprecatedRepos to migrate automatically", url, replacement)
+			}
+			return replacement, nil
+		}
+	}
+	return url, nil
+}
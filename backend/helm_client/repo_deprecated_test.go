@@ -0,0 +1,32 @@
+package helm_client
+
+import "testing"
+
+func TestCheckDeprecatedRepoBlocksWithoutFlag(t *testing.T) {
+	_, err := checkDeprecatedRepo("https://kubernetes-charts.storage.googleapis.com", false)
+	if err == nil {
+		t.Fatal("expected an error for a deprecated repo URL")
+	}
+}
+
+func TestCheckDeprecatedRepoRewritesWithFlag(t *testing.T) {
+	got, err := checkDeprecatedRepo("https://kubernetes-charts.storage.googleapis.com", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "https://charts.helm.sh/stable"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestCheckDeprecatedRepoLeavesOtherURLsAlone(t *testing.T) {
+	url := "https://charts.example.com"
+	got, err := checkDeprecatedRepo(url, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != url {
+		t.Fatalf("got %q, want %q", got, url)
+	}
+}
@@ -0,0 +1,48 @@
+package helm_client
+
+import (
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+type RepoRemoveOptions struct {
+	Names []string
+}
+
+func (cli *baseClient) repoRemove(o *RepoRemoveOptions) error {
+	repoFile := cli.settings.RepositoryConfig
+
+	// lock repo file, shared with repoAdd/repoUpdate via repositoryLock
+	unlock, err := acquireRepoLock(repoFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
+
+	b, err := os.ReadFile(repoFile)
+	if err != nil {
+		return err
+	}
+
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return err
+	}
+
+	for _, name := range o.Names {
+		if !f.Has(name) {
+			return errors.Errorf("no repo named %q found", name)
+		}
+		f.Remove(name)
+	}
+
+	return f.WriteFile(repoFile, 0644)
+}
@@ -0,0 +1,95 @@
+package helm_client
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+type RepoUpdateOptions struct {
+	Names []string // repo names to update; empty means update every configured repo
+}
+
+func (cli *baseClient) repoUpdate(o *RepoUpdateOptions) error {
+	repoFile := cli.settings.RepositoryConfig
+	repoCache := cli.settings.RepositoryCache
+
+	// lock repo file, shared with repoAdd/repoRemove via repositoryLock
+	unlock, err := acquireRepoLock(repoFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
+
+	b, err := os.ReadFile(repoFile)
+	if err != nil {
+		return err
+	}
+
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return err
+	}
+
+	if len(f.Repositories) == 0 {
+		return errors.New("no repositories found, please add one before updating")
+	}
+
+	var toUpdate []*repo.Entry
+	for _, cfg := range f.Repositories {
+		if len(o.Names) > 0 && !containsName(o.Names, cfg.Name) {
+			continue
+		}
+		toUpdate = append(toUpdate, cfg)
+	}
+	if len(o.Names) > 0 && len(toUpdate) != len(o.Names) {
+		return errors.Errorf("repo(s) not found: %v", o.Names)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(toUpdate))
+	for i, cfg := range toUpdate {
+		wg.Add(1)
+		go func(i int, cfg *repo.Entry) {
+			defer wg.Done()
+
+			r, err := repo.NewChartRepository(cfg, getter.All(cli.settings))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if repoCache != "" {
+				r.CachePath = repoCache
+			}
+			if _, err := r.DownloadIndexFile(); err != nil {
+				errs[i] = errors.Wrapf(err, "failed to update %q chart repository", cfg.Name)
+			}
+		}(i, cfg)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package helm_client
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/pkg/errors"
+)
+
+// timeout for acquiring a repo lock; a var so tests can shrink it
+var lockTimeout = 30 * time.Second
+
+// in-process state shared by goroutines waiting on a given repo file
+type repoState struct {
+	sem       chan struct{}
+	flock     *flock.Flock
+	flockHeld bool
+	refCount  int
+}
+
+// process-wide registry of repoState, keyed by the absolute lock file path
+var repositoryLock = struct {
+	mu     sync.Mutex
+	states map[string]*repoState
+}{
+	states: make(map[string]*repoState),
+}
+
+// lockFilePath derives the sibling ".lock" path for a repo file, the same
+// convention repoAdd used before this subsystem existed.
+func lockFilePath(repoFile string) string {
+	ext := filepath.Ext(repoFile)
+	if len(ext) > 0 && len(ext) < len(repoFile) {
+		return strings.TrimSuffix(repoFile, ext) + ".lock"
+	}
+	return repoFile + ".lock"
+}
+
+// acquireRepoLock serializes access to repoFile across goroutines in this
+// process and across processes via an on-disk flock; the caller must close
+// the returned io.Closer to release its hold.
+func acquireRepoLock(repoFile string) (io.Closer, error) {
+	path := lockFilePath(repoFile)
+
+	state := checkoutRepoState(path)
+
+	select {
+	case state.sem <- struct{}{}:
+	case <-time.After(lockTimeout):
+		releaseRepoState(path, state)
+		return nil, errors.Errorf("timed out waiting for lock on %q", path)
+	}
+
+	if !state.flockHeld {
+		lockCtx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+		locked, err := state.flock.TryLockContext(lockCtx, time.Second)
+		cancel()
+		if err != nil || !locked {
+			<-state.sem
+			releaseRepoState(path, state)
+			if err == nil {
+				err = errors.Errorf("timed out waiting for on-disk lock on %q", path)
+			}
+			return nil, err
+		}
+		state.flockHeld = true
+	}
+
+	return &repoUnlocker{path: path, state: state}, nil
+}
+
+// checkoutRepoState returns the repoState for path, creating it on first reference
+func checkoutRepoState(path string) *repoState {
+	repositoryLock.mu.Lock()
+	defer repositoryLock.mu.Unlock()
+
+	state, ok := repositoryLock.states[path]
+	if !ok {
+		state = &repoState{sem: make(chan struct{}, 1), flock: flock.New(path)}
+		repositoryLock.states[path] = state
+	}
+	state.refCount++
+	return state
+}
+
+// releaseRepoState drops the caller's reference, unlocking the flock once the last reference is gone
+func releaseRepoState(path string, state *repoState) error {
+	repositoryLock.mu.Lock()
+	state.refCount--
+	last := state.refCount == 0
+	if last {
+		delete(repositoryLock.states, path)
+	}
+	repositoryLock.mu.Unlock()
+
+	if last && state.flockHeld {
+		state.flockHeld = false
+		return state.flock.Unlock()
+	}
+	return nil
+}
+
+type repoUnlocker struct {
+	path  string
+	state *repoState
+	once  sync.Once
+}
+
+func (u *repoUnlocker) Close() error {
+	var err error
+	u.once.Do(func() {
+		err = releaseRepoState(u.path, u.state)
+		<-u.state.sem
+	})
+	return err
+}
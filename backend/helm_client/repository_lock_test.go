@@ -0,0 +1,164 @@
+package helm_client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireRepoLockSerializesSameFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+
+	unlock1, err := acquireRepoLock(path)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock2, err := acquireRepoLock(path)
+		if err != nil {
+			t.Errorf("second acquire: %v", err)
+			return
+		}
+		close(acquired)
+		if err := unlock2.Close(); err != nil {
+			t.Errorf("unlock2 close: %v", err)
+		}
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second acquirer proceeded before the first released the lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := unlock1.Close(); err != nil {
+		t.Fatalf("unlock1 close: %v", err)
+	}
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second acquirer never proceeded after the first released the lock")
+	}
+}
+
+func TestAcquireRepoLockDifferentFilesDontBlock(t *testing.T) {
+	pathA := filepath.Join(t.TempDir(), "a.yaml")
+	pathB := filepath.Join(t.TempDir(), "b.yaml")
+
+	unlockA, err := acquireRepoLock(pathA)
+	if err != nil {
+		t.Fatalf("acquire a: %v", err)
+	}
+	defer unlockA.Close()
+
+	done := make(chan struct{})
+	go func() {
+		unlockB, err := acquireRepoLock(pathB)
+		if err != nil {
+			t.Errorf("acquire b: %v", err)
+			return
+		}
+		defer unlockB.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locking a different repo file blocked on an unrelated lock")
+	}
+}
+
+func TestAcquireRepoLockTimesOut(t *testing.T) {
+	orig := lockTimeout
+	lockTimeout = 50 * time.Millisecond
+	defer func() { lockTimeout = orig }()
+
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+
+	unlock, err := acquireRepoLock(path)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	defer unlock.Close()
+
+	if _, err := acquireRepoLock(path); err == nil {
+		t.Fatal("expected second acquire to time out while the first holder is still active")
+	}
+}
+
+func TestAcquireRepoLockKeepsFlockAcrossHandoff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+
+	unlock1, err := acquireRepoLock(path)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	state1 := unlock1.(*repoUnlocker).state
+	if !state1.flockHeld {
+		t.Fatal("expected the first holder to hold the on-disk flock")
+	}
+
+	handedOff := make(chan *repoState, 1)
+	releaseSecond := make(chan struct{})
+	go func() {
+		unlock2, err := acquireRepoLock(path)
+		if err != nil {
+			t.Errorf("second acquire: %v", err)
+			return
+		}
+		handedOff <- unlock2.(*repoUnlocker).state
+		<-releaseSecond
+		unlock2.Close()
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the goroutine start waiting on the semaphore
+	if err := unlock1.Close(); err != nil {
+		t.Fatalf("unlock1 close: %v", err)
+	}
+
+	select {
+	case state2 := <-handedOff:
+		if state2 != state1 {
+			t.Fatal("the second holder should have reused the same repoState as the first, not created a new one")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second acquirer never proceeded")
+	}
+
+	// A second in-process holder was already waiting when unlock1 closed, so
+	// the flock must stay continuously held across the handoff rather than
+	// being released and re-acquired.
+	if !state1.flockHeld {
+		t.Fatal("flock should still be held across the in-process handoff to the second holder")
+	}
+
+	close(releaseSecond)
+	time.Sleep(50 * time.Millisecond) // let the second holder finish closing
+	if state1.flockHeld {
+		t.Fatal("flock should be released once the last holder closes")
+	}
+}
+
+func TestAcquireRepoLockReusableAfterRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repositories.yaml")
+
+	for i := 0; i < 3; i++ {
+		unlock, err := acquireRepoLock(path)
+		if err != nil {
+			t.Fatalf("iteration %d: acquire: %v", i, err)
+		}
+		if err := unlock.Close(); err != nil {
+			t.Fatalf("iteration %d: close: %v", i, err)
+		}
+	}
+
+	repositoryLock.mu.Lock()
+	defer repositoryLock.mu.Unlock()
+	if _, ok := repositoryLock.states[lockFilePath(path)]; ok {
+		t.Fatal("repoState was not cleaned up after the last holder released it")
+	}
+}
@@ -0,0 +1,252 @@
+package helm_client
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const fileRepoPrefix = "file://"
+
+// ResolveDependencies walks chartPath's Chart.yaml requirements and returns
+// a Chart.lock pinning each dependency to a concrete version, analogous to
+// `helm dependency update`. HTTP dependencies are resolved against the cached
+// repo index and OCI dependencies against the registry's published tags, both
+// using semver constraint matching; file:// dependencies are
+// resolved by loading the referenced local chart directly and recording its
+// actual Chart.Metadata.Version, since a local chart has no index to match a
+// range against.
+func (cli *baseClient) ResolveDependencies(chartPath string) (*chart.Lock, error) {
+	c, err := loader.LoadDir(chartPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load chart %q", chartPath)
+	}
+
+	deps := c.Metadata.Dependencies
+	locked := make([]*chart.Dependency, 0, len(deps))
+
+	for _, dep := range deps {
+		var version string
+		switch {
+		case strings.HasPrefix(dep.Repository, fileRepoPrefix):
+			version, err = cli.resolveLocalDependency(dep, chartPath)
+		case isOCIRepo(dep.Repository):
+			version, err = cli.resolveOCIDependency(dep)
+		default:
+			version, err = cli.resolveHTTPDependency(dep)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		locked = append(locked, &chart.Dependency{
+			Name:       dep.Name,
+			Repository: dep.Repository,
+			Version:    version,
+		})
+	}
+
+	digest, err := chartutil.HashReq(deps, locked)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash dependencies")
+	}
+
+	return &chart.Lock{
+		Generated:    time.Now(),
+		Digest:       digest,
+		Dependencies: locked,
+	}, nil
+}
+
+// resolveLocalDependency resolves a file:// dependency to the concrete
+// version recorded in the referenced chart's Chart.yaml, rather than the
+// user-supplied version range (e.g. "^0.1.0"), so that Chart.lock pins an
+// exact, reproducible version across dashboard nodes.
+func (cli *baseClient) resolveLocalDependency(dep *chart.Dependency, chartPath string) (string, error) {
+	depPath, err := GetLocalPath(dep.Repository, chartPath)
+	if err != nil {
+		return "", err
+	}
+
+	depChart, err := loader.LoadDir(depPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load local dependency %q", dep.Name)
+	}
+
+	return depChart.Metadata.Version, nil
+}
+
+// resolveHTTPDependency resolves an HTTP dependency's version range against
+// the cached repo index for dep.Repository.
+func (cli *baseClient) resolveHTTPDependency(dep *chart.Dependency) (string, error) {
+	constraint, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid version constraint %q for dependency %q", dep.Version, dep.Name)
+	}
+
+	repoName, err := cli.repoNameForURL(dep.Repository)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve repository for dependency %q", dep.Name)
+	}
+
+	indexFile, err := repo.LoadIndexFile(filepath.Join(cli.settings.RepositoryCache, cacheIndexFileName(repoName)))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load repo index for dependency %q", dep.Name)
+	}
+
+	versions, ok := indexFile.Entries[dep.Name]
+	if !ok || len(versions) == 0 {
+		return "", errors.Errorf("dependency %q not found in repo index", dep.Name)
+	}
+
+	version, err := matchConstraint(constraint, versionsOf(versions))
+	if err != nil {
+		return "", errors.Wrapf(err, "dependency %q", dep.Name)
+	}
+	return version, nil
+}
+
+// resolveOCIDependency resolves an OCI dependency's version range by
+// listing the tags published under the dependency's chart ref and
+// semver-matching against them, since OCI registries have no index.yaml
+// to load the way HTTP repos do.
+func (cli *baseClient) resolveOCIDependency(dep *chart.Dependency) (string, error) {
+	constraint, err := semver.NewConstraint(dep.Version)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid version constraint %q for dependency %q", dep.Version, dep.Name)
+	}
+
+	if _, err := cli.ociRepoNameForURL(dep.Repository); err != nil {
+		return "", errors.Wrapf(err, "failed to resolve registry for dependency %q", dep.Name)
+	}
+
+	if cli.registryClient == nil {
+		regClient, err := registry.NewClient(registry.ClientOptCredentialsFile(cli.settings.RegistryConfig))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create registry client")
+		}
+		cli.registryClient = regClient
+	}
+
+	host := strings.TrimPrefix(dep.Repository, "oci://")
+	ref := host + "/" + dep.Name
+
+	tags, err := cli.registryClient.Tags(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list tags for dependency %q", dep.Name)
+	}
+	if len(tags) == 0 {
+		return "", errors.Errorf("dependency %q not found in registry %q", dep.Name, dep.Repository)
+	}
+
+	version, err := matchConstraint(constraint, tags)
+	if err != nil {
+		return "", errors.Wrapf(err, "dependency %q", dep.Name)
+	}
+	return version, nil
+}
+
+// matchConstraint returns the first version in versions that satisfies
+// constraint, skipping any that don't parse as semver.
+func matchConstraint(constraint *semver.Constraints, versions []string) (string, error) {
+	for _, v := range versions {
+		ver, err := semver.NewVersion(v)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(ver) {
+			return v, nil
+		}
+	}
+	return "", errors.Errorf("no version satisfies constraint %q", constraint.String())
+}
+
+// versionsOf extracts the version strings from a repo index's chart
+// version list.
+func versionsOf(versions repo.ChartVersions) []string {
+	out := make([]string, len(versions))
+	for i, v := range versions {
+		out[i] = v.Version
+	}
+	return out
+}
+
+// repoNameForURL looks up the registered repo name for a dependency's
+// repository URL in repositories.yaml, since the on-disk index cache is
+// keyed by repo name (as written by repoAdd/repoUpdate), not by URL.
+func (cli *baseClient) repoNameForURL(url string) (string, error) {
+	// lock repo file, shared with repoAdd/repoUpdate/repoRemove via repositoryLock
+	unlock, err := acquireRepoLock(cli.settings.RepositoryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := unlock.Close(); err != nil {
+			log.Printf("Failed to unlock file: %v", err)
+		}
+	}()
+
+	b, err := os.ReadFile(cli.settings.RepositoryConfig)
+	if err != nil {
+		return "", err
+	}
+
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return "", err
+	}
+
+	for _, entry := range f.Repositories {
+		if entry.URL == url {
+			return entry.Name, nil
+		}
+	}
+
+	return "", errors.Errorf("no repository registered for url %q, run repo add first", url)
+}
+
+// GetLocalPath resolves depRepo (a file://... dependency repository)
+// relative to chartpath, rejecting any path that escapes chartpath's parent
+// directory.
+func GetLocalPath(depRepo, chartpath string) (string, error) {
+	var depPath string
+	var err error
+	p := strings.TrimPrefix(depRepo, fileRepoPrefix)
+
+	if !filepath.IsAbs(p) {
+		depPath, err = filepath.Abs(filepath.Join(chartpath, p))
+	} else {
+		depPath, err = filepath.Abs(p)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	parentDir, err := filepath.Abs(filepath.Dir(chartpath))
+	if err != nil {
+		return "", err
+	}
+
+	if depPath != parentDir && !strings.HasPrefix(depPath, parentDir+string(filepath.Separator)) {
+		return "", errors.Errorf("path %q is not within the parent directory of chart %q", depRepo, chartpath)
+	}
+
+	return depPath, nil
+}
+
+// cacheIndexFileName derives the cache filename repoUpdate uses for a
+// repo's index.yaml, keyed by repository name.
+func cacheIndexFileName(repoName string) string {
+	return repoName + "-index.yaml"
+}
@@ -0,0 +1,89 @@
+package helm_client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeChartYAML(t *testing.T, dir, name, version, extra string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("mkdir %q: %v", dir, err)
+	}
+	content := "apiVersion: v2\nname: " + name + "\nversion: " + version + "\n" + extra
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write Chart.yaml: %v", err)
+	}
+}
+
+func TestResolveDependenciesPinsConcreteVersionForFileDependency(t *testing.T) {
+	base := t.TempDir()
+
+	// The referenced local chart's real version, distinct from the range
+	// the dependent chart declares, so the test fails if ResolveDependencies
+	// ever regresses to echoing dep.Version back unchanged.
+	writeChartYAML(t, filepath.Join(base, "common"), "common", "1.2.3", "")
+
+	appDir := filepath.Join(base, "app")
+	writeChartYAML(t, appDir, "app", "0.1.0", `dependencies:
+  - name: common
+    version: "^1.0.0"
+    repository: "file://../common"
+`)
+
+	cli := &baseClient{}
+	lock, err := cli.ResolveDependencies(appDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(lock.Dependencies) != 1 {
+		t.Fatalf("got %d locked dependencies, want 1", len(lock.Dependencies))
+	}
+	if got := lock.Dependencies[0].Version; got != "1.2.3" {
+		t.Fatalf("got locked version %q, want the dependency's real Chart.Metadata.Version %q", got, "1.2.3")
+	}
+}
+
+func TestGetLocalPathWithinParentDir(t *testing.T) {
+	base := t.TempDir()
+	chartPath := filepath.Join(base, "app")
+
+	got, err := GetLocalPath("file://../common", chartPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := filepath.Join(base, "common")
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestGetLocalPathRejectsEscapingPath(t *testing.T) {
+	base := t.TempDir()
+	chartPath := filepath.Join(base, "app")
+
+	if _, err := GetLocalPath("file://../../etc", chartPath); err == nil {
+		t.Fatal("expected an error for a path escaping the parent directory")
+	}
+}
+
+func TestGetLocalPathRejectsPrefixSiblingDir(t *testing.T) {
+	// A sibling directory that merely shares a string prefix with the
+	// parent dir (e.g. "tenant-1" vs "tenant-10") must not be treated as
+	// contained within it.
+	base := t.TempDir()
+	chartPath := filepath.Join(base, "tenant-1", "app")
+	siblingAbs := filepath.Join(base, "tenant-10", "secret-chart")
+
+	rel, err := filepath.Rel(chartPath, siblingAbs)
+	if err != nil {
+		t.Fatalf("computing relative path: %v", err)
+	}
+
+	if _, err := GetLocalPath("file://"+rel, chartPath); err == nil {
+		t.Fatalf("expected %q to be rejected as outside the parent directory", siblingAbs)
+	}
+}